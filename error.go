@@ -1,8 +1,9 @@
 package nfs
 
 import (
-	"fmt"
-	"os"
+	"errors"
+	"strconv"
+	"syscall"
 )
 
 const (
@@ -36,24 +37,191 @@ const (
 	NFS3ERR_BADTYPE     = 10007
 )
 
+// errnoNames gives the stable Go constant name for a given NFS3 status, used
+// for Error.Error() so messages read as "NFS3ERR_NOENT" rather than a bare
+// status code.
+var errnoNames = map[uint32]string{
+	NFS3ERR_PERM:        "NFS3ERR_PERM",
+	NFS3ERR_NOENT:       "NFS3ERR_NOENT",
+	NFS3ERR_IO:          "NFS3ERR_IO",
+	NFS3ERR_NXIO:        "NFS3ERR_NXIO",
+	NFS3ERR_ACCES:       "NFS3ERR_ACCES",
+	NFS3ERR_EXIST:       "NFS3ERR_EXIST",
+	NFS3ERR_XDEV:        "NFS3ERR_XDEV",
+	NFS3ERR_NODEV:       "NFS3ERR_NODEV",
+	NFS3ERR_NOTDIR:      "NFS3ERR_NOTDIR",
+	NFS3ERR_ISDIR:       "NFS3ERR_ISDIR",
+	NFS3ERR_INVAL:       "NFS3ERR_INVAL",
+	NFS3ERR_FBIG:        "NFS3ERR_FBIG",
+	NFS3ERR_NOSPC:       "NFS3ERR_NOSPC",
+	NFS3ERR_ROFS:        "NFS3ERR_ROFS",
+	NFS3ERR_MLINK:       "NFS3ERR_MLINK",
+	NFS3ERR_NAMETOOLONG: "NFS3ERR_NAMETOOLONG",
+	NFS3ERR_NOTEMPTY:    "NFS3ERR_NOTEMPTY",
+	NFS3ERR_DQUOT:       "NFS3ERR_DQUOT",
+	NFS3ERR_STALE:       "NFS3ERR_STALE",
+	NFS3ERR_REMOTE:      "NFS3ERR_REMOTE",
+	NFS3ERR_BADHANDLE:   "NFS3ERR_BADHANDLE",
+	NFS3ERR_NOT_SYNC:    "NFS3ERR_NOT_SYNC",
+	NFS3ERR_BAD_COOKIE:  "NFS3ERR_BAD_COOKIE",
+	NFS3ERR_NOTSUPP:     "NFS3ERR_NOTSUPP",
+	NFS3ERR_TOOSMALL:    "NFS3ERR_TOOSMALL",
+	NFS3ERR_SERVERFAULT: "NFS3ERR_SERVERFAULT",
+	NFS3ERR_BADTYPE:     "NFS3ERR_BADTYPE",
+}
+
+// errnoSyscalls maps each NFS3 status to the syscall.Errno a local
+// filesystem call would have returned for the analogous condition. Codes
+// with no direct POSIX equivalent (the NFS-specific ones) are mapped to the
+// closest practical substitute so callers can still branch on IsX helpers
+// or a generic errors.Is(err, syscall.EIO)-style check.
+var errnoSyscalls = map[uint32]syscall.Errno{
+	NFS3ERR_PERM:        syscall.EPERM,
+	NFS3ERR_NOENT:       syscall.ENOENT,
+	NFS3ERR_IO:          syscall.EIO,
+	NFS3ERR_NXIO:        syscall.ENXIO,
+	NFS3ERR_ACCES:       syscall.EACCES,
+	NFS3ERR_EXIST:       syscall.EEXIST,
+	NFS3ERR_XDEV:        syscall.EXDEV,
+	NFS3ERR_NODEV:       syscall.ENODEV,
+	NFS3ERR_NOTDIR:      syscall.ENOTDIR,
+	NFS3ERR_ISDIR:       syscall.EISDIR,
+	NFS3ERR_INVAL:       syscall.EINVAL,
+	NFS3ERR_FBIG:        syscall.EFBIG,
+	NFS3ERR_NOSPC:       syscall.ENOSPC,
+	NFS3ERR_ROFS:        syscall.EROFS,
+	NFS3ERR_MLINK:       syscall.EMLINK,
+	NFS3ERR_NAMETOOLONG: syscall.ENAMETOOLONG,
+	NFS3ERR_NOTEMPTY:    syscall.ENOTEMPTY,
+	NFS3ERR_DQUOT:       syscall.EDQUOT,
+	NFS3ERR_STALE:       syscall.ESTALE,
+	NFS3ERR_REMOTE:      syscall.EREMOTE,
+	NFS3ERR_BADHANDLE:   syscall.EBADF,
+	NFS3ERR_NOT_SYNC:    syscall.EIO,
+	NFS3ERR_BAD_COOKIE:  syscall.ESTALE,
+	NFS3ERR_NOTSUPP:     syscall.ENOTSUP,
+	NFS3ERR_TOOSMALL:    syscall.EIO,
+	NFS3ERR_SERVERFAULT: syscall.EIO,
+	NFS3ERR_BADTYPE:     syscall.EINVAL,
+}
+
+// NFS3Error turns an NFS3 status code into an error, or nil for NFS3_OK.
 func NFS3Error(errnum uint32) error {
-	switch errnum {
-	case NFS3ERR_PERM:
-		return os.ErrPermission
-	case NFS3ERR_EXIST:
-		return os.ErrExist
-	case NFS3ERR_NOENT:
-		return os.ErrNotExist
-	default:
-		return &Error{fmt.Sprintf("error: %d", errnum)}
+	if errnum == NFS3_OK {
+		return nil
 	}
 
-	return nil
+	return &Error{Errno: errnum}
 }
 
-// Error represents an unexpected I/O behavior.
+// Error represents an NFS3 status code returned by a server. It carries the
+// numeric code alongside the syscall.Errno a local filesystem call would
+// have returned for the analogous condition, so callers can use
+// errors.Is/errors.As against ordinary syscall errnos instead of matching
+// on string text.
 type Error struct {
-	ErrorString string
+	// Errno is the raw NFS3ERR_* status code returned by the server.
+	Errno uint32
+}
+
+func (err *Error) Error() string {
+	if name, ok := errnoNames[err.Errno]; ok {
+		return "nfs: " + name
+	}
+	return "nfs: unknown error " + strconv.Itoa(int(err.Errno))
+}
+
+// Unwrap exposes the syscall.Errno analogous to this NFS3 status, so that
+// errors.Is(err, os.ErrNotExist) and friends (which compare against
+// syscall.Errno under the hood) keep working, alongside direct comparisons
+// like errors.Is(err, syscall.ENOSPC).
+func (err *Error) Unwrap() error {
+	if errno, ok := errnoSyscalls[err.Errno]; ok {
+		return errno
+	}
+	return syscall.EIO
+}
+
+func nfsErrno(err error) (uint32, bool) {
+	var e *Error
+	if !errors.As(err, &e) {
+		return 0, false
+	}
+	return e.Errno, true
+}
+
+// IsNotExist reports whether err indicates the object was not found.
+func IsNotExist(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_NOENT
+}
+
+// IsExist reports whether err indicates the object already exists.
+func IsExist(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_EXIST
 }
 
-func (err *Error) Error() string { return err.ErrorString }
+// IsPermission reports whether err indicates the request was denied due to
+// permissions (NFS3ERR_PERM or NFS3ERR_ACCES).
+func IsPermission(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && (code == NFS3ERR_PERM || code == NFS3ERR_ACCES)
+}
+
+// IsNotDirError reports whether err indicates a path component that was
+// expected to be a directory was not one.
+func IsNotDirError(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_NOTDIR
+}
+
+// IsDirError reports whether err indicates an operation expecting a
+// non-directory (e.g. a regular-file open) was given a directory instead.
+func IsDirError(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_ISDIR
+}
+
+// IsStale reports whether err indicates the file handle used no longer
+// refers to a valid object on the server (NFS3ERR_STALE), which typically
+// means any cached handle/attributes for it must be dropped.
+func IsStale(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_STALE
+}
+
+// IsNotEmpty reports whether err indicates a RMDIR was attempted against a
+// non-empty directory.
+func IsNotEmpty(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_NOTEMPTY
+}
+
+// IsNoSpace reports whether err indicates the server ran out of space or
+// quota to complete the write.
+func IsNoSpace(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && (code == NFS3ERR_NOSPC || code == NFS3ERR_DQUOT)
+}
+
+// IsBadCookie reports whether err indicates a READDIR/READDIRPLUS cookie was
+// rejected by the server, usually because the directory changed underneath
+// an in-progress listing.
+func IsBadCookie(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_BAD_COOKIE
+}
+
+// IsBadHandle reports whether err indicates the file handle was malformed.
+func IsBadHandle(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_BADHANDLE
+}
+
+// IsNotSupported reports whether err indicates the operation isn't
+// supported by the server.
+func IsNotSupported(err error) bool {
+	code, ok := nfsErrno(err)
+	return ok && code == NFS3ERR_NOTSUPP
+}
@@ -0,0 +1,254 @@
+package nfs
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCacheShards bounds lock contention: each shard owns an
+	// independent LRU and mutex, so unrelated lookups under different
+	// directories rarely block each other.
+	defaultCacheShards = 32
+
+	// negativeTTLDivisor derives the TTL for a cached ENOENT from the
+	// positive entryTimeout: negative entries are kept around for a much
+	// shorter window since a subsequent create of the same name is common
+	// and we don't want to keep lying about it.
+	negativeTTLDivisor = 4
+)
+
+// entry is a single cached lookup result, keyed by the full cleaned path it
+// was resolved from. A negative entry records that the path didn't exist as
+// of expire, so repeated stat()s against a missing file don't round-trip to
+// the server.
+type entry struct {
+	path     string
+	fh       []byte
+	attr     *Fattr
+	negative bool
+	expire   time.Time
+}
+
+// entryCache is a bounded, sharded LRU of path lookups. Sharding trades a
+// single global ordering for much lower contention, which matters once this
+// sits behind a FUSE mount issuing concurrent lookups from many kernel
+// threads.
+type entryCache struct {
+	shards []*entryCacheShard
+}
+
+type entryCacheShard struct {
+	mu    sync.Mutex
+	cap   int
+	items map[string]*list.Element // path -> element
+	order *list.List               // front = most recently used
+}
+
+func newEntryCache(size int) *entryCache {
+	if size <= 0 {
+		size = 1
+	}
+
+	shardCap := size / defaultCacheShards
+	if shardCap < 1 {
+		shardCap = 1
+	}
+
+	c := &entryCache{shards: make([]*entryCacheShard, defaultCacheShards)}
+	for i := range c.shards {
+		c.shards[i] = &entryCacheShard{
+			cap:   shardCap,
+			items: make(map[string]*list.Element),
+			order: list.New(),
+		}
+	}
+	return c
+}
+
+func shardKey(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32()) % n
+}
+
+func (c *entryCache) shardFor(path string) *entryCacheShard {
+	return c.shards[shardKey(path, len(c.shards))]
+}
+
+// get returns the cached entry for path, if present and unexpired.
+func (c *entryCache) get(path string) (*entry, bool) {
+	s := c.shardFor(path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[path]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if time.Now().After(e.expire) {
+		s.order.Remove(el)
+		delete(s.items, path)
+		return nil, false
+	}
+
+	s.order.MoveToFront(el)
+	return e, true
+}
+
+// put inserts or refreshes the cache entry for path, evicting the least
+// recently used entry in its shard if it's over capacity.
+func (c *entryCache) put(e *entry) {
+	s := c.shardFor(e.path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[e.path]; ok {
+		el.Value = e
+		s.order.MoveToFront(el)
+		return
+	}
+
+	el := s.order.PushFront(e)
+	s.items[e.path] = el
+
+	for s.order.Len() > s.cap {
+		oldest := s.order.Back()
+		if oldest == nil {
+			break
+		}
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*entry).path)
+	}
+}
+
+// forget removes path (and, since a rename/remove/mkdir invalidates
+// whatever the kernel/caller last saw for it, nothing else) from the cache.
+func (c *entryCache) forget(path string) {
+	s := c.shardFor(path)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[path]; ok {
+		s.order.Remove(el)
+		delete(s.items, path)
+	}
+}
+
+// reset drops every cached entry across all shards.
+func (c *entryCache) reset() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.items = make(map[string]*list.Element)
+		s.order.Init()
+		s.mu.Unlock()
+	}
+}
+
+// sweep drops expired entries across all shards; called periodically from
+// Target.cleanupCache.
+func (c *entryCache) sweep(now time.Time) {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for el := s.order.Back(); el != nil; {
+			prev := el.Prev()
+			e := el.Value.(*entry)
+			if now.After(e.expire) {
+				s.order.Remove(el)
+				delete(s.items, e.path)
+			}
+			el = prev
+		}
+		s.mu.Unlock()
+	}
+}
+
+// attrCacheEntry is a PostOpAttr piggy-backed off some other RPC, cached by
+// file handle so a FUSE Attr()/Getattr() call can often be answered without
+// a round trip.
+type attrCacheEntry struct {
+	attr   *Fattr
+	expire time.Time
+}
+
+// attrCache is a sharded, TTL-based cache of file handle -> attributes. It
+// isn't an LRU: handles are cheap and few enough relative to paths that
+// bounding it isn't worth the complexity; expiry alone keeps it in check.
+type attrCache struct {
+	shards []*attrCacheShard
+	ttl    time.Duration
+}
+
+type attrCacheShard struct {
+	mu      sync.Mutex
+	entries map[string]*attrCacheEntry
+}
+
+func newAttrCache(ttl time.Duration) *attrCache {
+	c := &attrCache{shards: make([]*attrCacheShard, defaultCacheShards), ttl: ttl}
+	for i := range c.shards {
+		c.shards[i] = &attrCacheShard{entries: make(map[string]*attrCacheEntry)}
+	}
+	return c
+}
+
+func (c *attrCache) shardFor(fh string) *attrCacheShard {
+	return c.shards[shardKey(fh, len(c.shards))]
+}
+
+func (c *attrCache) get(fh []byte) (*Fattr, bool) {
+	key := string(fh)
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expire) {
+		return nil, false
+	}
+	return e.attr, true
+}
+
+func (c *attrCache) put(fh []byte, attr *Fattr) {
+	if attr == nil {
+		return
+	}
+
+	key := string(fh)
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &attrCacheEntry{attr: attr, expire: time.Now().Add(c.ttl)}
+}
+
+func (c *attrCache) forget(fh []byte) {
+	key := string(fh)
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+func (c *attrCache) reset() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.entries = make(map[string]*attrCacheEntry)
+		s.mu.Unlock()
+	}
+}
+
+func (c *attrCache) sweep(now time.Time) {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, e := range s.entries {
+			if now.After(e.expire) {
+				delete(s.entries, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,214 @@
+// Package fusefs adapts an *nfs.Target into a bazil.org/fuse/fs.FS so an
+// NFSv3 export can be mounted directly with the OS's FUSE driver.
+package fusefs
+
+import (
+	"context"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+
+	"github.com/vmware/go-nfs-client/nfs"
+)
+
+// FS adapts a *nfs.Target to fs.FS.
+type FS struct {
+	target *nfs.Target
+
+	// entryTimeout bounds how long a Node's cached attributes are
+	// considered valid before FUSE re-Attr()s it.
+	entryTimeout time.Duration
+}
+
+// New returns a fs.FS backed by target. entryTimeout is used to derive the
+// fuse.Attr.Valid window handed back from each node's Attr call.
+func New(target *nfs.Target, entryTimeout time.Duration) *FS {
+	return &FS{target: target, entryTimeout: entryTimeout}
+}
+
+func (f *FS) Root() (fs.Node, error) {
+	return &Node{fs: f, path: "/"}, nil
+}
+
+// Node is both a fs.Node and, for regular files, a fuse Handle: reads and
+// writes operate directly against the NFS file handle rather than through a
+// separate open-file abstraction.
+type Node struct {
+	fs   *FS
+	path string
+
+	fh   []byte
+	info os.FileInfo
+}
+
+var (
+	_ fs.Node               = (*Node)(nil)
+	_ fs.NodeRequestLookuper = (*Node)(nil)
+	_ fs.HandleReadDirAller = (*Node)(nil)
+	_ fs.NodeCreater        = (*Node)(nil)
+	_ fs.NodeMkdirer        = (*Node)(nil)
+	_ fs.NodeRemover        = (*Node)(nil)
+	_ fs.NodeRenamer        = (*Node)(nil)
+	_ fs.NodeSetattrer      = (*Node)(nil)
+	_ fs.HandleReader       = (*Node)(nil)
+	_ fs.HandleWriter       = (*Node)(nil)
+)
+
+func (n *Node) Attr(ctx context.Context, a *fuse.Attr) error {
+	if n.fh != nil {
+		if info, ok := n.fs.target.CachedAttr(n.fh); ok {
+			n.info = info
+			fillAttr(a, n.fs.entryTimeout, info)
+			return nil
+		}
+	}
+
+	info, _, err := n.fs.target.LookupContext(ctx, n.path)
+	if err != nil {
+		return toErrno(err)
+	}
+	n.info = info
+
+	fillAttr(a, n.fs.entryTimeout, info)
+	return nil
+}
+
+func fillAttr(a *fuse.Attr, valid time.Duration, info os.FileInfo) {
+	a.Valid = valid
+	a.Mode = info.Mode()
+	a.Size = uint64(info.Size())
+	a.Mtime = info.ModTime()
+}
+
+func (n *Node) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (fs.Node, error) {
+	p := path.Join(n.path, req.Name)
+	info, fh, err := n.fs.target.LookupContext(ctx, p)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+
+	return &Node{fs: n.fs, path: p, fh: fh, info: info}, nil
+}
+
+func (n *Node) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	entries, err := n.fs.target.ReadDirPlusContext(ctx, n.path)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+
+	dirents := make([]fuse.Dirent, 0, len(entries))
+	for _, e := range entries {
+		if e.FileName == "." || e.FileName == ".." {
+			continue
+		}
+
+		typ := fuse.DT_File
+		if e.Attr.Attr.Type == nfs.NF3Dir {
+			typ = fuse.DT_Dir
+		}
+
+		dirents = append(dirents, fuse.Dirent{Name: e.FileName, Type: typ})
+	}
+
+	return dirents, nil
+}
+
+func (n *Node) Create(ctx context.Context, req *fuse.CreateRequest, resp *fuse.CreateResponse) (fs.Node, fs.Handle, error) {
+	p := path.Join(n.path, req.Name)
+	fh, err := n.fs.target.CreateContext(ctx, p, req.Mode)
+	if err != nil {
+		return nil, nil, toErrno(err)
+	}
+
+	child := &Node{fs: n.fs, path: p, fh: fh}
+	return child, child, nil
+}
+
+func (n *Node) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (fs.Node, error) {
+	p := path.Join(n.path, req.Name)
+	fh, err := n.fs.target.MkdirContext(ctx, p, req.Mode)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+
+	return &Node{fs: n.fs, path: p, fh: fh}, nil
+}
+
+func (n *Node) Remove(ctx context.Context, req *fuse.RemoveRequest) error {
+	p := path.Join(n.path, req.Name)
+	var err error
+	if req.Dir {
+		err = n.fs.target.RmDirContext(ctx, p)
+	} else {
+		err = n.fs.target.RemoveContext(ctx, p)
+	}
+	return toErrno(err)
+}
+
+func (n *Node) Rename(ctx context.Context, req *fuse.RenameRequest, newDir fs.Node) error {
+	// Target has no Rename yet; surface it as unsupported rather than
+	// silently no-op'ing the request.
+	return fuse.ENOTSUP
+}
+
+func (n *Node) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) error {
+	if req.Valid != 0 {
+		// Target has no SETATTR RPC yet: report the change as unsupported
+		// rather than silently dropping a chmod/truncate/utimes and
+		// telling the kernel it succeeded.
+		return fuse.ENOTSUP
+	}
+	return nil
+}
+
+func (n *Node) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
+	buf := make([]byte, req.Size)
+	nr, _, err := n.fs.target.ReadContext(ctx, n.fh, uint64(req.Offset), buf)
+	if err != nil {
+		return toErrno(err)
+	}
+
+	resp.Data = buf[:nr]
+	return nil
+}
+
+func (n *Node) Write(ctx context.Context, req *fuse.WriteRequest, resp *fuse.WriteResponse) error {
+	nw, err := n.fs.target.WriteContext(ctx, n.fh, uint64(req.Offset), req.Data)
+	if err != nil {
+		return toErrno(err)
+	}
+
+	resp.Size = nw
+	return nil
+}
+
+// toErrno translates an NFS3/Target error into the fuse.Errno the kernel
+// expects back from a FUSE op.
+func toErrno(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case nfs.IsNotExist(err):
+		return fuse.ENOENT
+	case nfs.IsPermission(err):
+		return fuse.EPERM
+	case nfs.IsExist(err):
+		return fuse.EEXIST
+	case nfs.IsNotDirError(err):
+		return fuse.Errno(syscall.ENOTDIR)
+	case nfs.IsDirError(err):
+		return fuse.Errno(syscall.EISDIR)
+	case nfs.IsNotEmpty(err):
+		return fuse.Errno(syscall.ENOTEMPTY)
+	case nfs.IsNoSpace(err):
+		return fuse.Errno(syscall.ENOSPC)
+	case nfs.IsStale(err):
+		return fuse.Errno(syscall.ESTALE)
+	default:
+		return fuse.EIO
+	}
+}
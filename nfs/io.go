@@ -0,0 +1,117 @@
+// Copyright © 2017 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: BSD-2-Clause
+//
+package nfs
+
+import (
+	"context"
+
+	"github.com/vmware/go-nfs-client/nfs/rpc"
+	"github.com/vmware/go-nfs-client/nfs/util"
+	"github.com/vmware/go-nfs-client/nfs/xdr"
+)
+
+// Read reads up to len(p) bytes from fh at offset. It returns the number of
+// bytes read and whether the server reported end-of-file.
+func (v *Target) Read(fh []byte, offset uint64, p []byte) (int, bool, error) {
+	return v.ReadContext(context.Background(), fh, offset, p)
+}
+
+func (v *Target) ReadContext(ctx context.Context, fh []byte, offset uint64, p []byte) (int, bool, error) {
+	type Read3Args struct {
+		rpc.Header
+		FH     []byte
+		Offset uint64
+		Count  uint32
+	}
+
+	type Read3Res struct {
+		Attr  PostOpAttr
+		Count uint32
+		Eof   bool
+		Data  []byte
+	}
+
+	res, err := v.callContext(ctx, &Read3Args{
+		Header: rpc.Header{
+			Rpcvers: 2,
+			Prog:    Nfs3Prog,
+			Vers:    Nfs3Vers,
+			Proc:    NFSProc3Read,
+			Cred:    v.auth,
+			Verf:    rpc.AuthNull,
+		},
+		FH:     fh,
+		Offset: offset,
+		Count:  uint32(len(p)),
+	})
+
+	if err != nil {
+		util.Debugf("read(%x, %d): %s", fh, offset, err.Error())
+		return 0, false, err
+	}
+
+	readres := new(Read3Res)
+	if err := xdr.Read(res, readres); err != nil {
+		util.Errorf("read(%x) failed to parse return: %s", fh, err)
+		return 0, false, err
+	}
+
+	n := copy(p, readres.Data)
+	return n, readres.Eof, nil
+}
+
+// Write writes p to fh at offset and returns the number of bytes the server
+// accepted.
+func (v *Target) Write(fh []byte, offset uint64, p []byte) (int, error) {
+	return v.WriteContext(context.Background(), fh, offset, p)
+}
+
+func (v *Target) WriteContext(ctx context.Context, fh []byte, offset uint64, p []byte) (int, error) {
+	type Write3Args struct {
+		rpc.Header
+		FH     []byte
+		Offset uint64
+		Count  uint32
+		Stable uint32
+		Data   []byte
+	}
+
+	type Write3Res struct {
+		Wcc       WccData
+		Count     uint32
+		Committed uint32
+		Verf      uint64
+	}
+
+	const unstable = 0
+
+	res, err := v.callContext(ctx, &Write3Args{
+		Header: rpc.Header{
+			Rpcvers: 2,
+			Prog:    Nfs3Prog,
+			Vers:    Nfs3Vers,
+			Proc:    NFSProc3Write,
+			Cred:    v.auth,
+			Verf:    rpc.AuthNull,
+		},
+		FH:     fh,
+		Offset: offset,
+		Count:  uint32(len(p)),
+		Stable: unstable,
+		Data:   p,
+	})
+
+	if err != nil {
+		util.Debugf("write(%x, %d): %s", fh, offset, err.Error())
+		return 0, err
+	}
+
+	writeres := new(Write3Res)
+	if err := xdr.Read(res, writeres); err != nil {
+		util.Errorf("write(%x) failed to parse return: %s", fh, err)
+		return 0, err
+	}
+
+	return int(writeres.Count), nil
+}
@@ -0,0 +1,312 @@
+// Package overlay composes one writable NFS target ("upper") with one or
+// more read-only NFS targets ("lower") into a single union view, the way
+// Linux overlayfs composes directories on one host.
+//
+// On-disk layout: nothing is stored outside the upper target itself, so any
+// number of clients mounting the same upper/lower NFS exports see the same
+// overlay. Deleting a name that still exists in a lower layer is recorded
+// by creating a zero-length whiteout file named ".wh.<name>" next to where
+// <name> would have lived in the upper layer; ReadDirPlus filters both the
+// whiteout marker and the name it hides out of its merged listing. Copy-up
+// is explicit: CopyUp copies the full contents and mode of a file that only
+// exists in a lower layer into the upper layer, after which Lookup serves
+// that path from upper. Overlay itself has no read or write methods, and
+// nothing is copied up unless CopyUp is called.
+package overlay
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/vmware/go-nfs-client/nfs"
+)
+
+const whiteoutPrefix = ".wh."
+
+func whiteoutName(name string) string { return whiteoutPrefix + name }
+
+// Overlay layers a writable upper *nfs.Target over one or more read-only
+// lower *nfs.Target values.
+type Overlay struct {
+	upper  *nfs.Target
+	lowers []*nfs.Target
+}
+
+// New returns an Overlay with upper as the writable layer and lowers
+// consulted, in order, for anything upper doesn't have.
+func New(upper *nfs.Target, lowers ...*nfs.Target) *Overlay {
+	return &Overlay{upper: upper, lowers: lowers}
+}
+
+// Lookup resolves p against upper first, falling through to each lower in
+// order, skipping any name upper has whited out.
+func (o *Overlay) Lookup(ctx context.Context, p string) (os.FileInfo, []byte, error) {
+	whited, err := o.whitedOut(ctx, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if whited {
+		return nil, nil, &nfs.Error{Errno: nfs.NFS3ERR_NOENT}
+	}
+
+	if info, fh, err := o.upper.LookupContext(ctx, p); err == nil {
+		return info, fh, nil
+	} else if !nfs.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	for _, lower := range o.lowers {
+		info, fh, err := lower.LookupContext(ctx, p)
+		if err == nil {
+			return info, fh, nil
+		}
+		if !nfs.IsNotExist(err) {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, &nfs.Error{Errno: nfs.NFS3ERR_NOENT}
+}
+
+// whitedOut reports whether upper has recorded a whiteout for p's name in
+// p's parent directory.
+func (o *Overlay) whitedOut(ctx context.Context, p string) (bool, error) {
+	dir, name := path.Split(path.Clean(p))
+	if name == "" || name == "." {
+		return false, nil
+	}
+
+	_, _, err := o.upper.LookupContext(ctx, path.Join(dir, whiteoutName(name)))
+	if err == nil {
+		return true, nil
+	}
+	if nfs.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// ReadDirPlus merges the directory listing of upper and every lower,
+// upper winning ties, filtering out whiteout markers and whatever name each
+// one hides.
+func (o *Overlay) ReadDirPlus(ctx context.Context, dir string) ([]*nfs.EntryPlus, error) {
+	seen := make(map[string]bool)
+	whiteouts := make(map[string]bool)
+	var merged []*nfs.EntryPlus
+
+	upperEntries, err := o.upper.ReadDirPlusContext(ctx, dir)
+	if err != nil && !nfs.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range upperEntries {
+		if strings.HasPrefix(e.FileName, whiteoutPrefix) {
+			whiteouts[strings.TrimPrefix(e.FileName, whiteoutPrefix)] = true
+			continue
+		}
+		if seen[e.FileName] {
+			continue
+		}
+		seen[e.FileName] = true
+		merged = append(merged, e)
+	}
+
+	for _, lower := range o.lowers {
+		entries, err := lower.ReadDirPlusContext(ctx, dir)
+		if err != nil {
+			if nfs.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, e := range entries {
+			if whiteouts[e.FileName] || seen[e.FileName] {
+				continue
+			}
+			seen[e.FileName] = true
+			merged = append(merged, e)
+		}
+	}
+
+	return merged, nil
+}
+
+// Create always creates in upper: there's nothing to copy up for a file
+// that doesn't exist anywhere yet.
+func (o *Overlay) Create(ctx context.Context, p string, perm os.FileMode) ([]byte, error) {
+	return o.upper.CreateContext(ctx, p, perm)
+}
+
+// Mkdir always creates in upper.
+func (o *Overlay) Mkdir(ctx context.Context, p string, perm os.FileMode) ([]byte, error) {
+	return o.upper.MkdirContext(ctx, p, perm)
+}
+
+// Remove deletes p from upper if it's there, and leaves (or creates) a
+// whiteout if p is still visible in a lower layer so it stays hidden from
+// ReadDirPlus and Lookup.
+func (o *Overlay) Remove(ctx context.Context, p string) error {
+	return o.remove(ctx, p, false)
+}
+
+// RmDir is Remove for a directory name.
+func (o *Overlay) RmDir(ctx context.Context, p string) error {
+	return o.remove(ctx, p, true)
+}
+
+func (o *Overlay) remove(ctx context.Context, p string, dir bool) error {
+	var upperErr error
+	if dir {
+		upperErr = o.upper.RmDirContext(ctx, p)
+	} else {
+		upperErr = o.upper.RemoveContext(ctx, p)
+	}
+	if upperErr != nil && !nfs.IsNotExist(upperErr) {
+		return upperErr
+	}
+
+	inLower, err := o.existsInLower(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if inLower {
+		if dir {
+			// Upper's own copy of p (if any) is already gone, so this is the
+			// same merged listing ReadDirPlus would produce for p: if it's
+			// non-empty, RMDIR must fail with NOTEMPTY rather than silently
+			// hiding every file still underneath behind a whiteout.
+			entries, err := o.ReadDirPlus(ctx, p)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if e.FileName == "." || e.FileName == ".." {
+					continue
+				}
+				return &nfs.Error{Errno: nfs.NFS3ERR_NOTEMPTY}
+			}
+		}
+		return o.writeWhiteout(ctx, p)
+	}
+
+	// Not in any lower: upperErr is nil if upper held p and removed it, or
+	// NOENT if no layer had it at all.
+	return upperErr
+}
+
+// RemoveAll recursively deletes p from the merged view, removing (or
+// whiting out, for anything still visible in a lower) every entry
+// underneath it before removing p itself.
+func (o *Overlay) RemoveAll(ctx context.Context, p string) error {
+	info, _, err := o.Lookup(ctx, p)
+	if err != nil {
+		if nfs.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !info.IsDir() {
+		return o.Remove(ctx, p)
+	}
+
+	entries, err := o.ReadDirPlus(ctx, p)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.FileName == "." || e.FileName == ".." {
+			continue
+		}
+		if err := o.RemoveAll(ctx, path.Join(p, e.FileName)); err != nil {
+			return err
+		}
+	}
+
+	return o.RmDir(ctx, p)
+}
+
+func (o *Overlay) existsInLower(ctx context.Context, p string) (bool, error) {
+	for _, lower := range o.lowers {
+		if _, _, err := lower.LookupContext(ctx, p); err == nil {
+			return true, nil
+		} else if !nfs.IsNotExist(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (o *Overlay) writeWhiteout(ctx context.Context, p string) error {
+	dir, name := path.Split(path.Clean(p))
+	whPath := path.Join(dir, whiteoutName(name))
+
+	_, err := o.upper.CreateContext(ctx, whPath, 0644)
+	if err != nil && !nfs.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+// CopyUp ensures p exists in upper, copying its data and mode over from
+// whichever lower currently serves it if necessary, and returns upper's
+// file handle for it. Callers should route every write through CopyUp
+// first so the write lands on the upper layer.
+func (o *Overlay) CopyUp(ctx context.Context, p string) ([]byte, error) {
+	if _, fh, err := o.upper.LookupContext(ctx, p); err == nil {
+		return fh, nil
+	} else if !nfs.IsNotExist(err) {
+		return nil, err
+	}
+
+	var (
+		srcInfo os.FileInfo
+		srcFh   []byte
+		src     *nfs.Target
+	)
+	for _, lower := range o.lowers {
+		info, fh, err := lower.LookupContext(ctx, p)
+		if err == nil {
+			srcInfo, srcFh, src = info, fh, lower
+			break
+		}
+		if !nfs.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if src == nil {
+		return nil, &nfs.Error{Errno: nfs.NFS3ERR_NOENT}
+	}
+
+	dstFh, err := o.upper.CreateContext(ctx, p, srcInfo.Mode())
+	if err != nil {
+		return nil, err
+	}
+
+	fsinfo, err := o.upper.FSInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := make([]byte, fsinfo.WTMax)
+	var offset uint64
+	for {
+		n, eof, err := src.ReadContext(ctx, srcFh, offset, chunk)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			if _, err := o.upper.WriteContext(ctx, dstFh, offset, chunk[:n]); err != nil {
+				return nil, err
+			}
+			offset += uint64(n)
+		}
+		if eof {
+			break
+		}
+	}
+
+	return dstFh, nil
+}
@@ -4,12 +4,13 @@
 package nfs
 
 import (
+	"context"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
-	"sync"
+	"syscall"
 	"time"
 
 	"github.com/vmware/go-nfs-client/nfs/rpc"
@@ -17,11 +18,11 @@ import (
 	"github.com/vmware/go-nfs-client/nfs/xdr"
 )
 
-type cacheEntry struct {
-	fh     []byte
-	attr   *Fattr
-	expire time.Time
-}
+// defaultCacheSize is used when NewTarget is given a cacheSize <= 0, which
+// keeps the old zero-value constructor call sites working without silently
+// disabling caching.
+const defaultCacheSize = 50000
+
 type Target struct {
 	*rpc.Client
 
@@ -31,11 +32,17 @@ type Target struct {
 	fsinfo  *FSInfo
 
 	entryTimeout time.Duration
-	cacheM       sync.Mutex
-	entries      map[string]map[string]*cacheEntry
+	entries      *entryCache
+	attrs        *attrCache
 }
 
-func NewTarget(addr string, auth rpc.Auth, fh []byte, dirpath string, entryTimeout time.Duration) (*Target, error) {
+// NewTarget dials addr and mounts fh as the root of the returned Target.
+// cacheSize bounds the number of path lookups kept in the LRU entry cache
+// (shared across positive and negative entries); a value <= 0 selects
+// defaultCacheSize. The bound is split evenly across the cache's shards,
+// rounding down but keeping at least one entry per shard, so a cacheSize
+// smaller than the shard count still allows one entry per shard.
+func NewTarget(addr string, auth rpc.Auth, fh []byte, dirpath string, entryTimeout time.Duration, cacheSize int) (*Target, error) {
 	m := rpc.Mapping{
 		Prog: Nfs3Prog,
 		Vers: Nfs3Vers,
@@ -48,13 +55,18 @@ func NewTarget(addr string, auth rpc.Auth, fh []byte, dirpath string, entryTimeo
 		return nil, err
 	}
 
+	if cacheSize <= 0 {
+		cacheSize = defaultCacheSize
+	}
+
 	vol := &Target{
 		Client:       client,
 		auth:         auth,
 		fh:           fh,
 		dirPath:      dirpath,
 		entryTimeout: entryTimeout,
-		entries:      make(map[string]map[string]*cacheEntry),
+		entries:      newEntryCache(cacheSize),
+		attrs:        newAttrCache(entryTimeout),
 	}
 
 	fsinfo, err := vol.FSInfo()
@@ -68,32 +80,70 @@ func NewTarget(addr string, auth rpc.Auth, fh []byte, dirpath string, entryTimeo
 	return vol, nil
 }
 
-// wraps the Call function to check status and decode errors
+// call is the context.Background() variant of callContext, kept for
+// callers that haven't been converted to thread a context through yet.
 func (v *Target) call(c interface{}) (io.ReadSeeker, error) {
-	res, err := v.Call(c)
-	if err != nil {
-		return nil, err
-	}
+	return v.callContext(context.Background(), c)
+}
 
-	status, err := xdr.ReadUint32(res)
-	if err != nil {
-		return nil, err
-	}
+// callContext wraps the Call function to check status and decode errors. It
+// races the underlying RPC call against ctx so that a cancelled or
+// deadline-expired context returns ctx.Err() instead of blocking until the
+// server replies.
+//
+// v.Client is the single connection shared by every concurrent caller of
+// this Target, so on cancellation we must not close it out from under
+// them: that would abort every other in-flight call along with this one,
+// and nothing here reconnects afterward. Instead we simply stop waiting;
+// the call's goroutine keeps running to completion in the background and
+// its result, once it arrives, is discarded. Aborting the specific
+// in-flight write on the wire would need a context-aware transport in
+// nfs/rpc (e.g. one connection/deadline per call), which is out of reach
+// from Target alone.
+func (v *Target) callContext(ctx context.Context, c interface{}) (io.ReadSeeker, error) {
+	type result struct {
+		res io.ReadSeeker
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		res, err := v.Call(c)
+		done <- result{res, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
 
-	if err = NFS3Error(status); err != nil {
-		return nil, err
-	}
+		status, err := xdr.ReadUint32(r.res)
+		if err != nil {
+			return nil, err
+		}
 
-	return res, nil
+		if err = NFS3Error(status); err != nil {
+			return nil, err
+		}
+
+		return r.res, nil
+	}
 }
 
 func (v *Target) FSInfo() (*FSInfo, error) {
+	return v.FSInfoContext(context.Background())
+}
+
+func (v *Target) FSInfoContext(ctx context.Context) (*FSInfo, error) {
 	type FSInfoArgs struct {
 		rpc.Header
 		FsRoot []byte
 	}
 
-	res, err := v.call(&FSInfoArgs{
+	res, err := v.callContext(ctx, &FSInfoArgs{
 		Header: rpc.Header{
 			Rpcvers: 2,
 			Prog:    Nfs3Prog,
@@ -120,99 +170,203 @@ func (v *Target) FSInfo() (*FSInfo, error) {
 
 func (v *Target) cleanupCache() {
 	for {
-		v.cacheM.Lock()
 		now := time.Now()
-		var cnt int
-	OUTER:
-		for fh, es := range v.entries {
-			for n, e := range es {
-				if now.After(e.expire) {
-					delete(es, n)
-					if len(es) == 0 {
-						delete(v.entries, fh)
-					}
-				}
-				cnt++
-				if cnt > 1000 {
-					break OUTER
-				}
-			}
-		}
-		v.cacheM.Unlock()
+		v.entries.sweep(now)
+		v.attrs.sweep(now)
 		time.Sleep(time.Second)
 	}
 }
 
-// Lookup returns attributes and the file handle to a given dirent
+// ForgetPath drops any cached lookup and attributes for p, without waiting
+// for entryTimeout to elapse. Call this after any operation that changes
+// what p resolves to (create, remove, rename, mkdir) instead of relying on
+// the TTL alone.
+func (v *Target) ForgetPath(p string) {
+	// resolve keys the entry cache by root-relative path ("foo/bar", or "."
+	// for the root), so normalise p the same way before looking it up.
+	clean := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if clean == "" {
+		clean = "."
+	}
+	if e, ok := v.entries.get(clean); ok && e.fh != nil {
+		v.attrs.forget(e.fh)
+	}
+	v.entries.forget(clean)
+}
+
+// ForgetAll drops every cached lookup and attribute Target holds.
+func (v *Target) ForgetAll() {
+	v.entries.reset()
+	v.attrs.reset()
+}
+
+// CachedAttr returns the attributes last piggy-backed off some RPC for fh,
+// if any and still within entryTimeout, without a round trip to the
+// server. Callers like fusefs's Attr()/Getattr() use this to avoid a LOOKUP
+// just to refresh attributes the Target already has fresh.
+func (v *Target) CachedAttr(fh []byte) (os.FileInfo, bool) {
+	attr, ok := v.attrs.get(fh)
+	if !ok {
+		return nil, false
+	}
+	return attr, true
+}
+
+// FollowMode selects how the terminal component of a path is resolved when
+// it turns out to be a symlink, mirroring POSIX open(2)'s O_NOFOLLOW.
+// Intermediate components are always followed, the same as the kernel does
+// for any path lookup.
+type FollowMode int
+
+const (
+	// FollowSymlink resolves a symlink in the terminal component, the same
+	// as a plain open(2) without O_NOFOLLOW. This is what Lookup/Stat use.
+	FollowSymlink FollowMode = iota
+	// NoFollowSymlink leaves a symlink in the terminal component
+	// unresolved, returning the symlink's own attributes and handle
+	// instead. This is what Lstat uses.
+	NoFollowSymlink
+)
+
+// maxSymlinkHops bounds how many symlinks LookupContext/StatContext will
+// transparently follow before giving up with syscall.ELOOP, matching Linux's
+// MAXSYMLINKS.
+const maxSymlinkHops = 40
+
+// Lookup returns attributes and the file handle to a given dirent,
+// following a symlink in the terminal path component. Equivalent to Stat.
 func (v *Target) Lookup(p string) (os.FileInfo, []byte, error) {
+	return v.LookupContext(context.Background(), p)
+}
+
+// LookupContext is Lookup with a context that, when cancelled or expired,
+// stops waiting on any in-flight RPC (see callContext) and stops the
+// traversal early.
+func (v *Target) LookupContext(ctx context.Context, p string) (os.FileInfo, []byte, error) {
+	hops := 0
+	return v.resolve(ctx, p, FollowSymlink, &hops)
+}
+
+// Stat is an alias for Lookup/LookupContext, provided for symmetry with
+// Lstat/LstatContext.
+func (v *Target) Stat(p string) (os.FileInfo, []byte, error) {
+	return v.Lookup(p)
+}
+
+func (v *Target) StatContext(ctx context.Context, p string) (os.FileInfo, []byte, error) {
+	return v.LookupContext(ctx, p)
+}
+
+// Lstat is Lookup but does not follow a symlink in the terminal path
+// component: if p itself names a symlink, its own attributes and handle are
+// returned rather than the target's.
+func (v *Target) Lstat(p string) (os.FileInfo, []byte, error) {
+	return v.LstatContext(context.Background(), p)
+}
+
+func (v *Target) LstatContext(ctx context.Context, p string) (os.FileInfo, []byte, error) {
+	hops := 0
+	return v.resolve(ctx, p, NoFollowSymlink, &hops)
+}
+
+// resolve descends p component by component from the mount root, following
+// any symlink encountered in a non-terminal component, and the terminal
+// component too unless follow is NoFollowSymlink. hops is shared across the
+// recursive calls a symlink triggers so the hop budget is enforced across
+// the whole resolution, not just one segment of it.
+func (v *Target) resolve(ctx context.Context, p string, follow FollowMode, hops *int) (os.FileInfo, []byte, error) {
 	var (
 		err   error
 		fattr *Fattr
 		fh    = v.fh
 	)
 
-	// desecend down a path heirarchy to get the last elem's fh
-	dirents := strings.Split(path.Clean(p), "/")
-	for _, dirent := range dirents {
+	clean := path.Clean(p)
+	soFar := ""
+
+	components := strings.Split(clean, "/")
+	for i, dirent := range components {
+		if err = ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
 		// we're assuming the root is always the root of the mount
 		if dirent == "" {
 			util.Debugf("root -> 0x%x", fh)
 			dirent = "."
 		}
 
-		fattr, fh, err = v.cachedLookup(fh, dirent)
+		childPath := path.Join(soFar, dirent)
+		fattr, fh, err = v.cachedLookup(ctx, childPath, fh, dirent)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		//util.Debugf("%s -> 0x%x", dirent, fh)
-		// TODO: resolve symlink
+		isTerminal := i == len(components)-1
+		if fattr.Type == NF3Lnk && (!isTerminal || follow == FollowSymlink) {
+			*hops++
+			if *hops > maxSymlinkHops {
+				return nil, nil, syscall.ELOOP
+			}
+
+			link, err := v.readlink(ctx, fh)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			rest := components[i+1:]
+			var next string
+			if path.IsAbs(link) {
+				next = path.Join(append([]string{link}, rest...)...)
+			} else {
+				next = path.Join(append([]string{soFar, link}, rest...)...)
+			}
+
+			return v.resolve(ctx, next, follow, hops)
+		}
+
+		soFar = childPath
 	}
 
 	return fattr, fh, nil
 }
 
-func (v *Target) parsefh(fh []byte) string {
-	return string(fh)
-}
-
-func (v *Target) cachedLookup(fh []byte, name string) (*Fattr, []byte, error) {
-	ino := v.parsefh(fh)
-	v.cacheM.Lock()
-	es := v.entries[ino]
-	if es != nil {
-		e := es[name]
-		if e != nil && time.Since(e.expire) < 0 {
-			v.cacheM.Unlock()
-			return e.attr, e.fh, nil
+// cachedLookup resolves name under fh, consulting (and populating) the
+// entry cache under the full path childPath so ForgetPath can later target
+// it directly, regardless of which parent directory fh it was reached
+// through.
+func (v *Target) cachedLookup(ctx context.Context, childPath string, fh []byte, name string) (*Fattr, []byte, error) {
+	if e, ok := v.entries.get(childPath); ok {
+		if e.negative {
+			return nil, nil, &Error{Errno: NFS3ERR_NOENT}
 		}
+		return e.attr, e.fh, nil
 	}
-	v.cacheM.Unlock()
-	attr, fh, err := v.lookup(fh, name)
-	if err == nil && attr.Type == 2 { // only cache directories
-		if es == nil {
-			es = make(map[string]*cacheEntry)
-			v.entries[ino] = es
+
+	attr, childFh, err := v.lookup(ctx, fh, name)
+	if err != nil {
+		if IsNotExist(err) {
+			v.entries.put(&entry{
+				path:     childPath,
+				negative: true,
+				expire:   time.Now().Add(v.entryTimeout / negativeTTLDivisor),
+			})
 		}
-		v.cacheM.Lock()
-		es[name] = &cacheEntry{fh, attr, time.Now().Add(v.entryTimeout)}
-		v.cacheM.Unlock()
+		return nil, nil, err
 	}
-	return attr, fh, err
-}
 
-func (v *Target) invalidateEntryCache(fh []byte, name string) {
-	ino := v.parsefh(fh)
-	v.cacheM.Lock()
-	es, ok := v.entries[ino]
-	if ok {
-		delete(es, name)
-	}
-	v.cacheM.Unlock()
+	v.entries.put(&entry{
+		path:   childPath,
+		fh:     childFh,
+		attr:   attr,
+		expire: time.Now().Add(v.entryTimeout),
+	})
+	v.attrs.put(childFh, attr)
+	return attr, childFh, nil
 }
 
 // lookup returns the same as above, but by fh and name
-func (v *Target) lookup(fh []byte, name string) (*Fattr, []byte, error) {
+func (v *Target) lookup(ctx context.Context, fh []byte, name string) (*Fattr, []byte, error) {
 	type Lookup3Args struct {
 		rpc.Header
 		What Diropargs3
@@ -224,7 +378,7 @@ func (v *Target) lookup(fh []byte, name string) (*Fattr, []byte, error) {
 		DirAttr PostOpAttr
 	}
 
-	res, err := v.call(&Lookup3Args{
+	res, err := v.callContext(ctx, &Lookup3Args{
 		Header: rpc.Header{
 			Rpcvers: 2,
 			Prog:    Nfs3Prog,
@@ -255,16 +409,59 @@ func (v *Target) lookup(fh []byte, name string) (*Fattr, []byte, error) {
 	return &lookupres.Attr.Attr, lookupres.FH, nil
 }
 
+// readlink implements NFSPROC3_READLINK: it returns the text a symlink's
+// file handle points at, unresolved.
+func (v *Target) readlink(ctx context.Context, fh []byte) (string, error) {
+	type Readlink3Args struct {
+		rpc.Header
+		FH []byte
+	}
+
+	type Readlink3Res struct {
+		SymlinkAttr PostOpAttr
+		Data        string
+	}
+
+	res, err := v.callContext(ctx, &Readlink3Args{
+		Header: rpc.Header{
+			Rpcvers: 2,
+			Prog:    Nfs3Prog,
+			Vers:    Nfs3Vers,
+			Proc:    NFSProc3Readlink,
+			Cred:    v.auth,
+			Verf:    rpc.AuthNull,
+		},
+		FH: fh,
+	})
+
+	if err != nil {
+		util.Debugf("readlink(%x): %s", fh, err.Error())
+		return "", err
+	}
+
+	readlinkres := new(Readlink3Res)
+	if err := xdr.Read(res, readlinkres); err != nil {
+		util.Errorf("readlink(%x) failed to parse return: %s", fh, err)
+		return "", err
+	}
+
+	return readlinkres.Data, nil
+}
+
 func (v *Target) ReadDirPlus(dir string) ([]*EntryPlus, error) {
-	_, fh, err := v.Lookup(dir)
+	return v.ReadDirPlusContext(context.Background(), dir)
+}
+
+func (v *Target) ReadDirPlusContext(ctx context.Context, dir string) ([]*EntryPlus, error) {
+	_, fh, err := v.LookupContext(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
 
-	return v.readDirPlus(fh)
+	return v.readDirPlus(ctx, fh)
 }
 
-func (v *Target) readDirPlus(fh []byte) ([]*EntryPlus, error) {
+func (v *Target) readDirPlus(ctx context.Context, fh []byte) ([]*EntryPlus, error) {
 	cookie := uint64(0)
 	cookieVerf := uint64(0)
 	eof := false
@@ -290,7 +487,7 @@ func (v *Target) readDirPlus(fh []byte) ([]*EntryPlus, error) {
 
 	var entries []*EntryPlus
 	for !eof {
-		res, err := v.call(&ReadDirPlus3Args{
+		res, err := v.callContext(ctx, &ReadDirPlus3Args{
 			Header: rpc.Header{
 				Rpcvers: 2,
 				Prog:    Nfs3Prog,
@@ -336,6 +533,9 @@ func (v *Target) readDirPlus(fh []byte) ([]*EntryPlus, error) {
 			}
 
 			cookie = item.Entry.Cookie
+			if item.Entry.Handle.IsSet && item.Entry.Attr.IsSet {
+				v.attrs.put(item.Entry.Handle.FH, &item.Entry.Attr.Attr)
+			}
 			entries = append(entries, &item.Entry)
 		}
 
@@ -353,8 +553,12 @@ func (v *Target) readDirPlus(fh []byte) ([]*EntryPlus, error) {
 
 // Creates a directory of the given name and returns its handle
 func (v *Target) Mkdir(path string, perm os.FileMode) ([]byte, error) {
+	return v.MkdirContext(context.Background(), path, perm)
+}
+
+func (v *Target) MkdirContext(ctx context.Context, path string, perm os.FileMode) ([]byte, error) {
 	dir, newDir := filepath.Split(path)
-	_, fh, err := v.Lookup(dir)
+	_, fh, err := v.LookupContext(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -391,7 +595,7 @@ func (v *Target) Mkdir(path string, perm os.FileMode) ([]byte, error) {
 			},
 		},
 	}
-	res, err := v.call(args)
+	res, err := v.callContext(ctx, args)
 
 	if err != nil {
 		util.Debugf("mkdir(%s): %s", path, err.Error())
@@ -405,15 +609,22 @@ func (v *Target) Mkdir(path string, perm os.FileMode) ([]byte, error) {
 		util.Debugf("mkdir(%s) partial response: %+v", mkdirres)
 		return nil, err
 	}
-	v.invalidateEntryCache(fh, newDir)
+	v.ForgetPath(path)
+	if mkdirres.Attr.IsSet {
+		v.attrs.put(mkdirres.FH.FH, &mkdirres.Attr.Attr)
+	}
 	util.Debugf("mkdir(%s): created successfully (0x%x)", path, fh)
 	return mkdirres.FH.FH, nil
 }
 
 // Create a file with name the given mode
 func (v *Target) Create(path string, perm os.FileMode) ([]byte, error) {
+	return v.CreateContext(context.Background(), path, perm)
+}
+
+func (v *Target) CreateContext(ctx context.Context, path string, perm os.FileMode) ([]byte, error) {
 	dir, newFile := filepath.Split(path)
-	_, fh, err := v.Lookup(dir)
+	_, fh, err := v.LookupContext(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -437,7 +648,7 @@ func (v *Target) Create(path string, perm os.FileMode) ([]byte, error) {
 		DirWcc WccData
 	}
 
-	res, err := v.call(&Create3Args{
+	res, err := v.callContext(ctx, &Create3Args{
 		Header: rpc.Header{
 			Rpcvers: 2,
 			Prog:    Nfs3Prog,
@@ -469,30 +680,41 @@ func (v *Target) Create(path string, perm os.FileMode) ([]byte, error) {
 	if err = xdr.Read(res, status); err != nil {
 		return nil, err
 	}
-	v.invalidateEntryCache(fh, newFile)
+	v.ForgetPath(path)
+	if status.Attr.IsSet {
+		v.attrs.put(status.FH.FH, &status.Attr.Attr)
+	}
 	util.Debugf("create(%s): created successfully", path)
 	return status.FH.FH, nil
 }
 
 // Remove a file
 func (v *Target) Remove(path string) error {
+	return v.RemoveContext(context.Background(), path)
+}
+
+func (v *Target) RemoveContext(ctx context.Context, path string) error {
 	parentDir, deleteFile := filepath.Split(path)
-	_, fh, err := v.Lookup(parentDir)
+	_, fh, err := v.LookupContext(ctx, parentDir)
 	if err != nil {
 		return err
 	}
 
-	return v.remove(fh, deleteFile)
+	if err := v.remove(ctx, fh, deleteFile); err != nil {
+		return err
+	}
+	v.ForgetPath(path)
+	return nil
 }
 
 // remove the named file from the parent (fh)
-func (v *Target) remove(fh []byte, deleteFile string) error {
+func (v *Target) remove(ctx context.Context, fh []byte, deleteFile string) error {
 	type RemoveArgs struct {
 		rpc.Header
 		Object Diropargs3
 	}
 
-	_, err := v.call(&RemoveArgs{
+	_, err := v.callContext(ctx, &RemoveArgs{
 		Header: rpc.Header{
 			Rpcvers: 2,
 			Prog:    Nfs3Prog,
@@ -511,29 +733,36 @@ func (v *Target) remove(fh []byte, deleteFile string) error {
 		util.Debugf("remove(%s): %s", deleteFile, err.Error())
 		return err
 	}
-	v.invalidateEntryCache(fh, deleteFile)
 	return nil
 }
 
 // RmDir removes a non-empty directory
 func (v *Target) RmDir(path string) error {
+	return v.RmDirContext(context.Background(), path)
+}
+
+func (v *Target) RmDirContext(ctx context.Context, path string) error {
 	dir, deletedir := filepath.Split(path)
-	_, fh, err := v.Lookup(dir)
+	_, fh, err := v.LookupContext(ctx, dir)
 	if err != nil {
 		return err
 	}
 
-	return v.rmDir(fh, deletedir)
+	if err := v.rmDir(ctx, fh, deletedir); err != nil {
+		return err
+	}
+	v.ForgetPath(path)
+	return nil
 }
 
 // delete the named directory from the parent directory (fh)
-func (v *Target) rmDir(fh []byte, name string) error {
+func (v *Target) rmDir(ctx context.Context, fh []byte, name string) error {
 	type RmDir3Args struct {
 		rpc.Header
 		Object Diropargs3
 	}
 
-	_, err := v.call(&RmDir3Args{
+	_, err := v.callContext(ctx, &RmDir3Args{
 		Header: rpc.Header{
 			Rpcvers: 2,
 			Prog:    Nfs3Prog,
@@ -552,22 +781,29 @@ func (v *Target) rmDir(fh []byte, name string) error {
 		util.Debugf("rmdir(%s): %s", name, err.Error())
 		return err
 	}
-	v.invalidateEntryCache(fh, name)
 	util.Debugf("rmdir(%s): deleted successfully", name)
 	return nil
 }
 
 func (v *Target) RemoveAll(path string) error {
+	return v.RemoveAllContext(context.Background(), path)
+}
+
+func (v *Target) RemoveAllContext(ctx context.Context, path string) error {
 	parentDir, deleteDir := filepath.Split(path)
-	_, parentDirfh, err := v.Lookup(parentDir)
+	_, parentDirfh, err := v.LookupContext(ctx, parentDir)
 	if err != nil {
 		return err
 	}
 
 	// Easy path.  This is a directory and it's empty.  If not a dir or not an
 	// empty dir, this will throw an error.
-	err = v.rmDir(parentDirfh, deleteDir)
-	if err == nil || os.IsNotExist(err) {
+	err = v.rmDir(ctx, parentDirfh, deleteDir)
+	if err == nil {
+		v.ForgetPath(path)
+		return nil
+	}
+	if IsNotExist(err) {
 		return nil
 	}
 
@@ -576,31 +812,36 @@ func (v *Target) RemoveAll(path string) error {
 		return err
 	}
 
-	_, deleteDirfh, err := v.lookup(parentDirfh, deleteDir)
+	_, deleteDirfh, err := v.lookup(ctx, parentDirfh, deleteDir)
 	if err != nil {
 		return err
 	}
 
-	if err = v.removeAll(deleteDirfh); err != nil {
+	if err = v.removeAll(ctx, path, deleteDirfh); err != nil {
 		return err
 	}
 
 	// Delete the directory we started at.
-	if err = v.rmDir(parentDirfh, deleteDir); err != nil {
+	if err = v.rmDir(ctx, parentDirfh, deleteDir); err != nil {
 		return err
 	}
 
+	v.ForgetPath(path)
 	return nil
 }
 
-// removeAll removes the deleteDir recursively
-func (v *Target) removeAll(deleteDirfh []byte) error {
+// removeAll removes the deleteDir recursively. dirPath is the full path
+// deleteDirfh was reached through, threaded down purely so each descendant
+// removed along the way can be evicted from the entry cache by its own full
+// path: the cache is keyed by path, not by handle, so there's no other way
+// to find it from here.
+func (v *Target) removeAll(ctx context.Context, dirPath string, deleteDirfh []byte) error {
 
 	// BFS the dir tree recursively.  If dir, recurse, then delete the dir and
 	// all files.
 
 	// This is a directory, get all of its Entries
-	entries, err := v.readDirPlus(deleteDirfh)
+	entries, err := v.readDirPlus(ctx, deleteDirfh)
 	if err != nil {
 		return err
 	}
@@ -611,26 +852,29 @@ func (v *Target) removeAll(deleteDirfh []byte) error {
 			continue
 		}
 
+		childPath := path.Join(dirPath, entry.FileName)
+
 		// If directory, recurse, then nuke it.  It should be empty when we get
 		// back.
 		if entry.Attr.Attr.Type == NF3Dir {
 			if entry.Handle.IsSet {
-				if err = v.removeAll(entry.Handle.FH); err != nil {
+				if err = v.removeAll(ctx, childPath, entry.Handle.FH); err != nil {
 					return err
 				}
 			}
 
-			err = v.rmDir(deleteDirfh, entry.FileName)
+			err = v.rmDir(ctx, deleteDirfh, entry.FileName)
 		} else {
 
 			// nuke all files
-			err = v.remove(deleteDirfh, entry.FileName)
+			err = v.remove(ctx, deleteDirfh, entry.FileName)
 		}
 
 		if err != nil {
 			util.Errorf("error deleting %s: %s", entry.FileName, err.Error())
 			return err
 		}
+		v.ForgetPath(childPath)
 	}
 
 	return nil
@@ -0,0 +1,177 @@
+// Package webdavfs adapts an *nfs.Target to golang.org/x/net/webdav.FileSystem
+// so an NFSv3 export can be served over HTTP to clients that have no NFS
+// mount of their own (browsers, mobile apps, Finder-over-HTTPS).
+package webdavfs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+
+	"golang.org/x/net/webdav"
+
+	"github.com/vmware/go-nfs-client/nfs"
+)
+
+// FS adapts a *nfs.Target to webdav.FileSystem.
+type FS struct {
+	target *nfs.Target
+}
+
+// New returns a webdav.FileSystem backed by target.
+func New(target *nfs.Target) *FS {
+	return &FS{target: target}
+}
+
+func (fs *FS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	_, err := fs.target.MkdirContext(ctx, name, perm)
+	return toWebdavErr(err)
+}
+
+func (fs *FS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	info, fh, err := fs.target.LookupContext(ctx, name)
+	switch {
+	case err == nil:
+		if flag&os.O_EXCL != 0 {
+			return nil, os.ErrExist
+		}
+	case nfs.IsNotExist(err) && flag&os.O_CREATE != 0:
+		fh, err = fs.target.CreateContext(ctx, name, perm)
+		if err != nil {
+			return nil, toWebdavErr(err)
+		}
+		info, _, err = fs.target.LookupContext(ctx, name)
+		if err != nil {
+			return nil, toWebdavErr(err)
+		}
+	default:
+		return nil, toWebdavErr(err)
+	}
+
+	return &file{target: fs.target, name: name, fh: fh, info: info}, nil
+}
+
+func (fs *FS) RemoveAll(ctx context.Context, name string) error {
+	return toWebdavErr(fs.target.RemoveAllContext(ctx, name))
+}
+
+func (fs *FS) Rename(ctx context.Context, oldName, newName string) error {
+	// Target has no RENAME3 call yet.
+	return &nfs.Error{Errno: nfs.NFS3ERR_NOTSUPP}
+}
+
+func (fs *FS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	info, _, err := fs.target.LookupContext(ctx, name)
+	if err != nil {
+		return nil, toWebdavErr(err)
+	}
+	return info, nil
+}
+
+// file implements webdav.File (http.File plus Write) on top of Target's
+// Read/Write/ReadDirPlus RPCs.
+type file struct {
+	target *nfs.Target
+	name   string
+	fh     []byte
+	info   os.FileInfo
+
+	offset  int64
+	dirents []os.FileInfo
+}
+
+func (f *file) Close() error { return nil }
+
+func (f *file) Read(p []byte) (int, error) {
+	n, eof, err := f.target.ReadContext(context.Background(), f.fh, uint64(f.offset), p)
+	if err != nil {
+		return 0, toWebdavErr(err)
+	}
+	f.offset += int64(n)
+	if n == 0 && eof {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	n, err := f.target.WriteContext(context.Background(), f.fh, uint64(f.offset), p)
+	if err != nil {
+		return 0, toWebdavErr(err)
+	}
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.offset = offset
+	case io.SeekCurrent:
+		f.offset += offset
+	case io.SeekEnd:
+		f.offset = f.info.Size() + offset
+	default:
+		return 0, os.ErrInvalid
+	}
+	return f.offset, nil
+}
+
+func (f *file) Readdir(count int) ([]os.FileInfo, error) {
+	if f.dirents == nil {
+		entries, err := f.target.ReadDirPlusContext(context.Background(), f.name)
+		if err != nil {
+			return nil, toWebdavErr(err)
+		}
+
+		for _, e := range entries {
+			if e.FileName == "." || e.FileName == ".." {
+				continue
+			}
+
+			info, _, err := f.target.LookupContext(context.Background(), path.Join(f.name, e.FileName))
+			if err != nil {
+				return nil, toWebdavErr(err)
+			}
+			f.dirents = append(f.dirents, info)
+		}
+	}
+
+	if count <= 0 {
+		infos := f.dirents
+		f.dirents = nil
+		return infos, nil
+	}
+
+	if len(f.dirents) == 0 {
+		return nil, io.EOF
+	}
+
+	n := count
+	if n > len(f.dirents) {
+		n = len(f.dirents)
+	}
+	infos := f.dirents[:n]
+	f.dirents = f.dirents[n:]
+	return infos, nil
+}
+
+func (f *file) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+func toWebdavErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case nfs.IsNotExist(err):
+		return os.ErrNotExist
+	case nfs.IsExist(err):
+		return os.ErrExist
+	case nfs.IsPermission(err):
+		return os.ErrPermission
+	default:
+		return err
+	}
+}